@@ -0,0 +1,156 @@
+package routesum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/stamp/routesum/pkg/routesum/rstrie"
+)
+
+// snapshotMagic identifies the binary snapshot format written by WriteTo and read by ReadFrom.
+var snapshotMagic = [4]byte{'r', 's', 'u', 'm'}
+
+const snapshotVersion = 1
+
+// MarshalBinary encodes rs's current contents into routesum's binary snapshot format. See WriteTo for the
+// format's details.
+func (rs *RouteSum) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if _, err := rs.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces rs's contents with the snapshot encoded in data by MarshalBinary.
+func (rs *RouteSum) UnmarshalBinary(data []byte) error {
+	_, err := rs.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// WriteTo writes rs to w in routesum's binary snapshot format and returns the number of bytes written. The
+// format is a small header (a magic number, a format version, and the number of IPv4 and IPv6 routes that
+// follow, as uvarints), followed by a preorder encoding of the IPv4 trie and then the IPv6 trie; see
+// rstrie.RSTrie.WriteTo for how each trie is encoded. Because the encoding mirrors the trie's own
+// structure, ReadFrom can rebuild it directly in O(n), without going through InsertRoute, which is what
+// makes this format suitable for persisting and reloading large summaries.
+func (rs *RouteSum) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, 0, len(snapshotMagic)+1+2*binary.MaxVarintLen64)
+	header = append(header, snapshotMagic[:]...)
+	header = append(header, snapshotVersion)
+	header = binary.AppendUvarint(header, uint64(rs.ipv4.LeafCount()))
+	header = binary.AppendUvarint(header, uint64(rs.ipv6.LeafCount()))
+
+	written := int64(0)
+
+	n, err := w.Write(header)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	n64, err := rs.ipv4.WriteTo(w)
+	written += n64
+	if err != nil {
+		return written, err
+	}
+
+	n64, err = rs.ipv6.WriteTo(w)
+	written += n64
+	if err != nil {
+		return written, err
+	}
+
+	return written, nil
+}
+
+// ReadFrom reads a snapshot written by WriteTo from r, replacing rs's contents, and returns the number of
+// bytes consumed.
+func (rs *RouteSum) ReadFrom(r io.Reader) (int64, error) {
+	header := make([]byte, len(snapshotMagic)+1)
+
+	read := int64(0)
+
+	n, err := io.ReadFull(r, header)
+	read += int64(n)
+	if err != nil {
+		return read, fmt.Errorf("read snapshot header: %w", err)
+	}
+
+	if !bytes.Equal(header[:len(snapshotMagic)], snapshotMagic[:]) {
+		return read, fmt.Errorf("not a routesum snapshot")
+	}
+
+	if version := header[len(snapshotMagic)]; version != snapshotVersion {
+		return read, fmt.Errorf("unsupported routesum snapshot version %d", version)
+	}
+
+	cr := &countingByteReader{r: r}
+
+	v4Count, err := binary.ReadUvarint(cr)
+	read += cr.n
+	if err != nil {
+		return read, fmt.Errorf("read IPv4 route count: %w", err)
+	}
+
+	cr.n = 0
+
+	v6Count, err := binary.ReadUvarint(cr)
+	read += cr.n
+	if err != nil {
+		return read, fmt.Errorf("read IPv6 route count: %w", err)
+	}
+
+	ipv4 := rstrie.NewRSTrie()
+	if v4Count > 0 {
+		n64, err := ipv4.ReadFrom(r)
+		read += n64
+		if err != nil {
+			return read, fmt.Errorf("read IPv4 routes: %w", err)
+		}
+
+		if got := ipv4.LeafCount(); uint64(got) != v4Count {
+			return read, fmt.Errorf("IPv4 route count mismatch: header says %d, got %d", v4Count, got)
+		}
+	}
+
+	ipv6 := rstrie.NewRSTrie()
+	if v6Count > 0 {
+		n64, err := ipv6.ReadFrom(r)
+		read += n64
+		if err != nil {
+			return read, fmt.Errorf("read IPv6 routes: %w", err)
+		}
+
+		if got := ipv6.LeafCount(); uint64(got) != v6Count {
+			return read, fmt.Errorf("IPv6 route count mismatch: header says %d, got %d", v6Count, got)
+		}
+	}
+
+	rs.ipv4 = ipv4
+	rs.ipv6 = ipv6
+
+	return read, nil
+}
+
+// countingByteReader adapts an io.Reader into an io.ByteReader for reading a single uvarint, tracking the
+// number of bytes consumed without reading ahead.
+type countingByteReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingByteReader) ReadByte() (byte, error) {
+	var buf [1]byte
+	if _, err := io.ReadFull(c.r, buf[:]); err != nil {
+		return 0, err
+	}
+
+	c.n++
+
+	return buf[0], nil
+}
@@ -69,6 +69,50 @@ func (rs *RouteSum) InsertFromString(s string) error {
 	return nil
 }
 
+// RemoveFromString removes either a string-formatted network or IP from the summary.
+func (rs *RouteSum) RemoveFromString(s string) error {
+	var ip netip.Addr
+	var ipBits bitslice.BitSlice
+	var err error
+
+	if strings.Contains(s, "/") {
+		ipPrefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return fmt.Errorf("parse network: %w", err)
+		}
+		if !ipPrefix.IsValid() {
+			return errors.Errorf("%s is not valid CIDR", s)
+		}
+
+		ip = ipPrefix.Addr()
+		ipBits, err = ipBitsForIPPrefix(ipPrefix)
+		if err != nil {
+			return err
+		}
+	} else {
+		ip, err = netip.ParseAddr(s)
+		if err != nil {
+			return fmt.Errorf("parse IP: %w", err)
+		}
+		if !ip.IsValid() {
+			return errors.Errorf("%s is not a valid IP", s)
+		}
+
+		ipBits, err = ipBitsForIP(ip)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ip.Is4() {
+		rs.ipv4.RemoveRoute(ipBits)
+	} else {
+		rs.ipv6.RemoveRoute(ipBits)
+	}
+
+	return nil
+}
+
 func ipBitsForIPPrefix(ipPrefix netip.Prefix) (bitslice.BitSlice, error) {
 	ipBytes, err := ipPrefix.Addr().MarshalBinary()
 	if err != nil {
@@ -97,6 +141,85 @@ func ipBitsForIP(ip netip.Addr) (bitslice.BitSlice, error) {
 	return ipBits, nil
 }
 
+// Contains returns whether ip is covered by a route already in the summary.
+func (rs *RouteSum) Contains(ip netip.Addr) bool {
+	ipBits, err := ipBitsForIP(ip)
+	if err != nil {
+		return false
+	}
+
+	if ip.Is4() {
+		return rs.ipv4.Contains(ipBits)
+	}
+
+	return rs.ipv6.Contains(ipBits)
+}
+
+// ContainsPrefix returns whether p is covered by a route already in the summary.
+func (rs *RouteSum) ContainsPrefix(p netip.Prefix) bool {
+	ipBits, err := ipBitsForIPPrefix(p)
+	if err != nil {
+		return false
+	}
+
+	if p.Addr().Is4() {
+		return rs.ipv4.Contains(ipBits)
+	}
+
+	return rs.ipv6.Contains(ipBits)
+}
+
+// LongestPrefixMatch returns the longest route in the summary that covers ip, and whether such a route was found.
+func (rs *RouteSum) LongestPrefixMatch(ip netip.Addr) (netip.Prefix, bool) {
+	ipBits, err := ipBitsForIP(ip)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+
+	if ip.Is4() {
+		matchBits, ok := rs.ipv4.LongestMatch(ipBits)
+		if !ok {
+			return netip.Prefix{}, false
+		}
+
+		return netip.PrefixFrom(ipv4FromBits(matchBits), len(matchBits)), true
+	}
+
+	matchBits, ok := rs.ipv6.LongestMatch(ipBits)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+
+	return netip.PrefixFrom(ipv6FromBits(matchBits), len(matchBits)), true
+}
+
+// Union returns a new RouteSum covering every route covered by rs, other, or both.
+func (rs *RouteSum) Union(other *RouteSum) *RouteSum {
+	result := new(RouteSum)
+	result.ipv4 = rs.ipv4.Union(other.ipv4)
+	result.ipv6 = rs.ipv6.Union(other.ipv6)
+
+	return result
+}
+
+// Intersect returns a new RouteSum covering every route covered by both rs and other.
+func (rs *RouteSum) Intersect(other *RouteSum) *RouteSum {
+	result := new(RouteSum)
+	result.ipv4 = rs.ipv4.Intersect(other.ipv4)
+	result.ipv6 = rs.ipv6.Intersect(other.ipv6)
+
+	return result
+}
+
+// Subtract returns a new RouteSum covering every route covered by rs but not other.
+func (rs *RouteSum) Subtract(other *RouteSum) *RouteSum {
+	result := new(RouteSum)
+	result.ipv4 = rs.ipv4.Subtract(other.ipv4)
+	result.ipv6 = rs.ipv6.Subtract(other.ipv6)
+
+	return result
+}
+
 // SummaryStrings returns a summary of all received routes as a string slice.
 func (rs *RouteSum) SummaryStrings() []string {
 	strs := []string{}
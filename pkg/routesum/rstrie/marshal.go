@@ -0,0 +1,208 @@
+package rstrie
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/PatrickCronin/routesum/pkg/routesum/bitslice"
+)
+
+const (
+	nodeKindInternal byte = 0
+	nodeKindLeaf     byte = 1
+)
+
+// maxBitLen is the longest prefix rstrie ever stores: an IPv6 address is 128 bits. ReadFrom rejects any
+// encoded node whose bits exceed it, since that can only come from a corrupt or hostile snapshot rather than
+// one this package wrote itself.
+const maxBitLen = 128
+
+// maxTrieDepth bounds how many nodes ReadFrom will recurse through while rebuilding a trie. A legitimate
+// trie can't nest an internal node per bit of address space plus more, so this also rejects a snapshot
+// engineered to exhaust the stack before bitLen would catch it.
+const maxTrieDepth = maxBitLen + 1
+
+// LeafCount returns the number of routes stored in the trie, equivalent to len(t.Contents()) but without
+// allocating a slice to hold them.
+func (t *RSTrie) LeafCount() int {
+	n := 0
+
+	t.Walk(func(bitslice.BitSlice) bool {
+		n++
+		return true
+	})
+
+	return n
+}
+
+// WriteTo writes a preorder encoding of the trie to w and returns the number of bytes written. For each
+// node, it writes a kind byte (0 for an internal node, 1 for a leaf), a varint giving the number of bits
+// in that node's own edge, and the ceil(bits/8) bytes holding them, packed MSB-first. An internal node's
+// encoding is immediately followed by its two children's encodings, in order. An empty trie writes
+// nothing.
+func (t *RSTrie) WriteTo(w io.Writer) (int64, error) {
+	if t.root == nil {
+		return 0, nil
+	}
+
+	return writeNode(w, t.root)
+}
+
+func writeNode[T comparable](w io.Writer, n *node[T]) (int64, error) {
+	var written int64
+
+	kind := nodeKindLeaf
+	if !n.isLeaf() {
+		kind = nodeKindInternal
+	}
+
+	nw, err := w.Write([]byte{kind})
+	written += int64(nw)
+	if err != nil {
+		return written, err
+	}
+
+	nw64, err := writeBits(w, n.bits)
+	written += nw64
+	if err != nil {
+		return written, err
+	}
+
+	if n.isLeaf() {
+		return written, nil
+	}
+
+	for _, child := range n.children {
+		nw64, err := writeNode(w, child)
+		written += nw64
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+func writeBits(w io.Writer, bits bitslice.BitSlice) (int64, error) {
+	var written int64
+
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	lenN := binary.PutUvarint(lenBuf, uint64(len(bits)))
+
+	n, err := w.Write(lenBuf[:lenN])
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	n, err = w.Write(bits.ToBytes((len(bits) + 7) / 8))
+	written += int64(n)
+
+	return written, err
+}
+
+// ReadFrom reads a preorder encoding written by WriteTo from r, replacing the trie's contents, and returns
+// the number of bytes consumed. It rebuilds the node structure directly from the stream, without calling
+// Insert.
+func (t *RSTrie) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+
+	root, err := readNode[struct{}](cr, 0)
+	if err != nil {
+		return cr.n, err
+	}
+
+	t.root = root
+
+	return cr.n, nil
+}
+
+// countingReader adapts a plain io.Reader into an io.ByteReader too, tracking the number of bytes consumed
+// without ever reading ahead of what's asked for, so that a RouteSum can share one underlying stream
+// across its header and both of its tries.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	var buf [1]byte
+	if _, err := io.ReadFull(c.r, buf[:]); err != nil {
+		return 0, err
+	}
+
+	c.n++
+
+	return buf[0], nil
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(c.r, p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+func readNode[T comparable](r *countingReader, depth int) (*node[T], error) {
+	if depth > maxTrieDepth {
+		return nil, fmt.Errorf("rstrie: snapshot nests deeper than the maximum trie depth of %d", maxTrieDepth)
+	}
+
+	kind, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	if kind != nodeKindInternal && kind != nodeKindLeaf {
+		return nil, fmt.Errorf("rstrie: unrecognized node kind %d", kind)
+	}
+
+	bits, err := readBits(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == nodeKindLeaf {
+		return &node[T]{bits: bits}, nil
+	}
+
+	left, err := readNode[T](r, depth+1)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := readNode[T](r, depth+1)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &node[T]{bits: bits, children: &[2]*node[T]{}}
+	n.children[0] = left
+	n.children[1] = right
+
+	return n, nil
+}
+
+func readBits(r *countingReader) (bitslice.BitSlice, error) {
+	bitLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if bitLen > maxBitLen {
+		return nil, fmt.Errorf("rstrie: snapshot's bit length %d exceeds the maximum of %d", bitLen, maxBitLen)
+	}
+
+	buf := make([]byte, (bitLen+7)/8)
+	if _, err := r.Read(buf); err != nil {
+		return nil, err
+	}
+
+	bits, err := bitslice.NewFromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return bits[:bitLen], nil
+}
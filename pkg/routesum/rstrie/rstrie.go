@@ -7,65 +7,77 @@ import (
 	"github.com/PatrickCronin/routesum/pkg/routesum/bitslice"
 )
 
-// RSTrie is a radix-like trie of radix 2 whose stored "words" are the binary representations of networks and IPs. An
-// optimization rstrie makes over a generic radix tree is that since routes covered by other routes don't need to be
-// stored, each node in the trie will have either 0 or 2 children; never 1.
-type RSTrie struct {
-	root *node
+// Trie is a radix-like trie of radix 2 whose stored "words" are the binary representations of networks and
+// IPs, with each stored word carrying a value of type T. An optimization Trie makes over a generic radix
+// tree is that since routes covered by other routes don't need to be stored, each node in the trie will
+// have either 0 or 2 children; never 1. Two sibling routes are only merged into a single, shorter route
+// when their values compare equal; otherwise they remain distinct leaves.
+type Trie[T comparable] struct {
+	root *node[T]
 }
 
-type node struct {
-	children *[2]*node
+type node[T comparable] struct {
+	children *[2]*node[T]
 	bits     bitslice.BitSlice
+	value    T
 }
 
-// NewRSTrie returns an initialized RSTrie for use
-func NewRSTrie() *RSTrie {
-	return &RSTrie{
+// NewTrie returns an initialized Trie for use.
+func NewTrie[T comparable]() *Trie[T] {
+	return &Trie[T]{
 		root: nil,
 	}
 }
 
-// InsertRoute inserts a new BitSlice into the trie. Each insert results in a space-optimized trie structure
-// representing its contents. If a route being inserted is already covered by an existing route, it's simply ignored. If
-// a route being inserted covers one or more routes already in the trie, those nodes are removed and replaced by the new
-// route.
-func (t *RSTrie) InsertRoute(routeBits bitslice.BitSlice) {
+// Insert associates value with bits in the trie. If bits is already covered by an existing route with an
+// equal value, the insert is a no-op. If bits is covered by an existing route with a different value, that
+// route is split so that bits can carry its own value. If bits covers one or more routes already in the
+// trie, those routes are removed and replaced by bits.
+func (t *Trie[T]) Insert(bits bitslice.BitSlice, value T) {
 	// If the trie has no root node, simply create one to store the new route
 	if t.root == nil {
-		t.root = &node{
-			bits:     routeBits,
+		t.root = &node[T]{
+			bits:     bits,
+			value:    value,
 			children: nil,
 		}
 		return
 	}
 
 	// Otherwise, perform a non-recursive search of the trie's nodes for the best place to insert the route, and do so.
-	visited := []*node{}
+	visited := []*node[T]{}
 	curNode := t.root
-	remainingRouteBits := routeBits
+	remainingBits := bits
 
 	for {
-		remainingRouteBitsLen := len(remainingRouteBits)
+		remainingBitsLen := len(remainingBits)
 		curNodeBitsLen := len(curNode.bits)
 
 		// Does the requested route cover the current node? If so, update the current node.
-		if remainingRouteBitsLen <= curNodeBitsLen && bytes.HasPrefix(curNode.bits, remainingRouteBits) {
-			curNode.bits = remainingRouteBits
+		if remainingBitsLen <= curNodeBitsLen && bytes.HasPrefix(curNode.bits, remainingBits) {
+			curNode.bits = remainingBits
+			curNode.value = value
 			curNode.children = nil
+			simplifyVisitedSubtries(visited)
 			return
 		}
 
-		if curNodeBitsLen <= remainingRouteBitsLen && bytes.HasPrefix(remainingRouteBits, curNode.bits) {
-			// Does the current node cover the requested route? If so, we're done.
+		if curNodeBitsLen <= remainingBitsLen && bytes.HasPrefix(remainingBits, curNode.bits) {
+			// Does the current node cover the requested route? If so, and the values agree, we're done.
 			if curNode.isLeaf() {
+				if curNode.value == value {
+					return
+				}
+
+				// The new, more specific route carries a different value: carve it out of the leaf.
+				splitLeafForInsert(curNode, remainingBits, value)
 				return
 			}
 
 			// Otherwise, we traverse to the correct child.
-			remainingRouteBits = remainingRouteBits[curNodeBitsLen:]
+			remainingBits = remainingBits[curNodeBitsLen:]
 			visited = append(visited, curNode)
-			curNode = curNode.children[remainingRouteBits[0]]
+			curNode = curNode.children[remainingBits[0]]
 			continue
 		}
 
@@ -74,12 +86,13 @@ func (t *RSTrie) InsertRoute(routeBits bitslice.BitSlice) {
 		// As an optimization, if the split would result in a new node whose children represent a complete subtrie, we
 		// just update the current node, instead of allocating new nodes and optimizing them away immediately after.
 		if curNode.isLeaf() &&
-			curNodeBitsLen == remainingRouteBitsLen &&
-			commonPrefixLen(curNode.bits, remainingRouteBits) == len(curNode.bits)-1 {
+			curNodeBitsLen == remainingBitsLen &&
+			curNode.value == value &&
+			commonPrefixLen(curNode.bits, remainingBits) == len(curNode.bits)-1 {
 			curNode.bits = curNode.bits[:len(curNode.bits)-1]
 			curNode.children = nil
 		} else {
-			newNode := splitNodeForRoute(curNode, remainingRouteBits)
+			newNode := splitNodeForRoute(curNode, remainingBits, value)
 			visitedLen := len(visited)
 			if visitedLen == 0 {
 				t.root = newNode
@@ -93,7 +106,159 @@ func (t *RSTrie) InsertRoute(routeBits bitslice.BitSlice) {
 	}
 }
 
-func (n *node) childrenAreCompleteSubtrie() bool {
+// splitLeafForInsert replaces curNode, a leaf whose bits are a strict prefix of remainingBits, with
+// curNode's former route (unaffected by the new, more specific one) alongside a new leaf for
+// remainingBits, since the two values differ and so can't be represented by a single leaf.
+func splitLeafForInsert[T comparable](curNode *node[T], remainingBits bitslice.BitSlice, value T) {
+	oldValue := curNode.value
+	pos := len(curNode.bits)
+	complementBit := flipBit(remainingBits[pos])
+
+	curNode.children = &[2]*node[T]{}
+	curNode.children[complementBit] = &node[T]{bits: bitslice.BitSlice{complementBit}, value: oldValue}
+	curNode.children[remainingBits[pos]] = buildInsertTail(remainingBits, pos+1, oldValue, value)
+}
+
+// buildInsertTail builds the subtree attached at a parent's children[routeBits[pos-1]], continuing the
+// divergence from pos onward: a complement leaf carrying oldValue branches off at each remaining position,
+// until pos reaches the end of routeBits, where the exact route is stored with value.
+func buildInsertTail[T comparable](routeBits bitslice.BitSlice, pos int, oldValue, value T) *node[T] {
+	selectorBit := routeBits[pos-1]
+
+	if pos == len(routeBits) {
+		return &node[T]{bits: bitslice.BitSlice{selectorBit}, value: value}
+	}
+
+	complementBit := flipBit(routeBits[pos])
+
+	tailNode := &node[T]{
+		bits:     bitslice.BitSlice{selectorBit},
+		children: &[2]*node[T]{},
+	}
+	tailNode.children[complementBit] = &node[T]{bits: bitslice.BitSlice{complementBit}, value: oldValue}
+	tailNode.children[routeBits[pos]] = buildInsertTail(routeBits, pos+1, oldValue, value)
+
+	return tailNode
+}
+
+// Remove removes routeBits from the address space covered by the trie. If routeBits isn't covered by any
+// stored route, Remove is a no-op. If routeBits is covered by a broader stored route, that route's node is
+// split into the minimal set of sibling routes covering what remains, each carrying the original route's
+// value.
+func (t *Trie[T]) Remove(routeBits bitslice.BitSlice) {
+	if t.root == nil {
+		return
+	}
+
+	visited := []*node[T]{}
+	curNode := t.root
+	remainingRouteBits := routeBits
+
+	for {
+		remainingRouteBitsLen := len(remainingRouteBits)
+		curNodeBitsLen := len(curNode.bits)
+
+		// Does the route to remove cover the current node? If so, the node (and everything below it) is
+		// removed entirely.
+		if remainingRouteBitsLen <= curNodeBitsLen && bytes.HasPrefix(curNode.bits, remainingRouteBits) {
+			removeNode(t, visited, curNode)
+			return
+		}
+
+		if curNodeBitsLen < remainingRouteBitsLen && bytes.HasPrefix(remainingRouteBits, curNode.bits) {
+			// Does the current node cover the route to remove? If it's a leaf, it needs to be split into
+			// the complement of the removed route.
+			if curNode.isLeaf() {
+				splitLeafForRemoval(curNode, remainingRouteBits)
+				return
+			}
+
+			// Otherwise, we traverse to the correct child.
+			remainingRouteBits = remainingRouteBits[curNodeBitsLen:]
+			visited = append(visited, curNode)
+			curNode = curNode.children[remainingRouteBits[0]]
+			continue
+		}
+
+		// Otherwise the route to remove diverges from the current node: it isn't covered by the trie.
+		return
+	}
+}
+
+// removeNode deletes curNode from the trie, given the chain of ancestors that led to it. Deleting a node
+// leaves its parent with a single remaining child, which is spliced into the parent's place to preserve
+// the "0 or 2 children" invariant.
+func removeNode[T comparable](t *Trie[T], visited []*node[T], curNode *node[T]) {
+	if len(visited) == 0 {
+		t.root = nil
+		return
+	}
+
+	parent := visited[len(visited)-1]
+
+	siblingBit := byte(0)
+	if parent.children[0] == curNode {
+		siblingBit = 1
+	}
+	sibling := parent.children[siblingBit]
+	sibling.bits = append(append(bitslice.BitSlice{}, parent.bits...), sibling.bits...)
+
+	if len(visited) == 1 {
+		t.root = sibling
+	} else {
+		grandparent := visited[len(visited)-2]
+		grandparent.children[sibling.bits[0]] = sibling
+	}
+
+	simplifyVisitedSubtries(visited[:len(visited)-1])
+}
+
+// splitLeafForRemoval replaces curNode, a leaf whose bits are a strict prefix of remainingRouteBits, with
+// the minimal set of sibling routes covering curNode's former range minus remainingRouteBits, each carrying
+// curNode's original value.
+func splitLeafForRemoval[T comparable](curNode *node[T], remainingRouteBits bitslice.BitSlice) {
+	oldValue := curNode.value
+	pos := len(curNode.bits)
+	lastPos := len(remainingRouteBits) - 1
+	complementBit := flipBit(remainingRouteBits[pos])
+
+	if pos == lastPos {
+		curNode.bits = append(curNode.bits, complementBit)
+		return
+	}
+
+	curNode.children = &[2]*node[T]{}
+	curNode.children[complementBit] = &node[T]{bits: bitslice.BitSlice{complementBit}, value: oldValue}
+	curNode.children[remainingRouteBits[pos]] = removalComplementNode(remainingRouteBits, pos+1, oldValue)
+}
+
+// removalComplementNode builds the subtree attached at a parent's children[routeBits[pos-1]], continuing
+// the complement expansion from pos onward. Once pos reaches the final divergent bit, the selector bit
+// that led here and the final complement bit are merged onto a single leaf, since nothing survives on the
+// route's own side.
+func removalComplementNode[T comparable](routeBits bitslice.BitSlice, pos int, oldValue T) *node[T] {
+	lastPos := len(routeBits) - 1
+	complementBit := flipBit(routeBits[pos])
+
+	if pos == lastPos {
+		return &node[T]{bits: bitslice.BitSlice{routeBits[pos-1], complementBit}, value: oldValue}
+	}
+
+	tailNode := &node[T]{
+		bits:     bitslice.BitSlice{routeBits[pos-1]},
+		children: &[2]*node[T]{},
+	}
+	tailNode.children[complementBit] = &node[T]{bits: bitslice.BitSlice{complementBit}, value: oldValue}
+	tailNode.children[routeBits[pos]] = removalComplementNode(routeBits, pos+1, oldValue)
+
+	return tailNode
+}
+
+func flipBit(b byte) byte {
+	return 1 - b
+}
+
+func (n *node[T]) childrenAreCompleteSubtrie() bool {
 	if n.isLeaf() {
 		return false
 	}
@@ -106,26 +271,31 @@ func (n *node) childrenAreCompleteSubtrie() bool {
 		return false
 	}
 
+	if n.children[0].value != n.children[1].value {
+		return false
+	}
+
 	return true
 }
 
-func (n *node) isLeaf() bool {
+func (n *node[T]) isLeaf() bool {
 	return n.children == nil
 }
 
-func splitNodeForRoute(oldNode *node, routeBits bitslice.BitSlice) *node {
+func splitNodeForRoute[T comparable](oldNode *node[T], routeBits bitslice.BitSlice, value T) *node[T] {
 	commonBitsLen := commonPrefixLen(oldNode.bits, routeBits)
 	commonBits := oldNode.bits[:commonBitsLen]
 
-	routeNode := &node{
+	routeNode := &node[T]{
 		bits:     routeBits[commonBitsLen:],
+		value:    value,
 		children: nil,
 	}
 	oldNode.bits = oldNode.bits[commonBitsLen:]
 
-	newNode := &node{
+	newNode := &node[T]{
 		bits:     commonBits,
-		children: &[2]*node{},
+		children: &[2]*node[T]{},
 	}
 	newNode.children[routeNode.bits[0]] = routeNode
 	newNode.children[oldNode.bits[0]] = oldNode
@@ -138,7 +308,7 @@ func splitNodeForRoute(oldNode *node, routeBits bitslice.BitSlice) *node {
 // would be representing the "000" and "001" routes. But that's the same as having a single node for "00".
 // simplifyCompletedSubtries takes a stack of visited nodes and simplifies completed subtries as far down the stack as
 // possible. If at any point in the stack we find a node representing an incomplete subtrie, we stop.
-func simplifyVisitedSubtries(visited []*node) {
+func simplifyVisitedSubtries[T comparable](visited []*node[T]) {
 	for i := len(visited) - 1; i >= 0; i-- {
 		if visited[i].isLeaf() {
 			return
@@ -148,6 +318,7 @@ func simplifyVisitedSubtries(visited []*node) {
 			return
 		}
 
+		visited[i].value = visited[i].children[0].value
 		visited[i].children = nil
 	}
 }
@@ -172,20 +343,95 @@ func min(a, b int) int {
 	return b
 }
 
-type traversalStep struct {
-	n                  *node
+// Get returns the value associated with bits if bits is stored in the trie exactly, and whether it was
+// found. Unlike LongestMatch, Get doesn't consider routes that merely cover bits.
+func (t *Trie[T]) Get(bits bitslice.BitSlice) (T, bool) {
+	var zero T
+
+	if t.root == nil {
+		return zero, false
+	}
+
+	curNode := t.root
+	remainingBits := bits
+
+	for {
+		curNodeBitsLen := len(curNode.bits)
+		if curNodeBitsLen > len(remainingBits) || !bytes.HasPrefix(remainingBits, curNode.bits) {
+			return zero, false
+		}
+
+		remainingBits = remainingBits[curNodeBitsLen:]
+
+		if len(remainingBits) == 0 {
+			if curNode.isLeaf() {
+				return curNode.value, true
+			}
+			return zero, false
+		}
+
+		if curNode.isLeaf() {
+			return zero, false
+		}
+
+		curNode = curNode.children[remainingBits[0]]
+	}
+}
+
+// Contains returns whether bits is covered by a route already stored in the trie.
+func (t *Trie[T]) Contains(bits bitslice.BitSlice) bool {
+	_, _, ok := t.LongestMatch(bits)
+	return ok
+}
+
+// LongestMatch returns the longest stored route that covers bits, its associated value, and whether such a
+// route was found.
+func (t *Trie[T]) LongestMatch(bits bitslice.BitSlice) (bitslice.BitSlice, T, bool) {
+	var zero T
+
+	if t.root == nil {
+		return nil, zero, false
+	}
+
+	precedingBits := bitslice.BitSlice{}
+	curNode := t.root
+	remainingBits := bits
+
+	for {
+		curNodeBitsLen := len(curNode.bits)
+		if curNodeBitsLen > len(remainingBits) || !bytes.HasPrefix(remainingBits, curNode.bits) {
+			return nil, zero, false
+		}
+
+		precedingBits = append(precedingBits, curNode.bits...)
+
+		if curNode.isLeaf() {
+			return precedingBits, curNode.value, true
+		}
+
+		remainingBits = remainingBits[curNodeBitsLen:]
+		if len(remainingBits) == 0 {
+			return nil, zero, false
+		}
+
+		curNode = curNode.children[remainingBits[0]]
+	}
+}
+
+type traversalStep[T comparable] struct {
+	n                  *node[T]
 	precedingRouteBits bitslice.BitSlice
 }
 
-// Contents returns the BitSlices contained in the RSTrie.
-func (t *RSTrie) Contents() []bitslice.BitSlice {
+// Contents returns the BitSlices contained in the Trie.
+func (t *Trie[T]) Contents() []bitslice.BitSlice {
 	// If the trie is empty
 	if t.root == nil {
 		return []bitslice.BitSlice{}
 	}
 
 	// Otherwise
-	queue := []traversalStep{
+	queue := []traversalStep[T]{
 		{
 			n:                  t.root,
 			precedingRouteBits: bitslice.BitSlice{},
@@ -204,7 +450,7 @@ func (t *RSTrie) Contents() []bitslice.BitSlice {
 		if step.n.isLeaf() {
 			contents = append(contents, stepRouteBits)
 		} else {
-			queue = append([]traversalStep{
+			queue = append([]traversalStep[T]{
 				{
 					n:                  step.n.children[0],
 					precedingRouteBits: stepRouteBits,
@@ -218,4 +464,277 @@ func (t *RSTrie) Contents() []bitslice.BitSlice {
 	}
 
 	return contents
-}
\ No newline at end of file
+}
+
+// Walk performs a depth-first traversal of the trie, calling yield with each stored route in turn, and
+// stops early if yield returns false. Unlike Contents, Walk doesn't materialize a queue of traversalSteps;
+// it grows and shrinks a single bit buffer as it descends and backtracks. The BitSlice passed to yield
+// aliases that buffer, so callers that need to keep it beyond the yield call must copy it.
+func (t *Trie[T]) Walk(yield func(bits bitslice.BitSlice) bool) {
+	if t.root == nil {
+		return
+	}
+
+	buf := bitslice.BitSlice{}
+	walkNode(t.root, &buf, yield)
+}
+
+func walkNode[T comparable](n *node[T], buf *bitslice.BitSlice, yield func(bitslice.BitSlice) bool) bool {
+	pushed := len(n.bits)
+	*buf = append(*buf, n.bits...)
+	defer func() {
+		*buf = (*buf)[:len(*buf)-pushed]
+	}()
+
+	if n.isLeaf() {
+		return yield(*buf)
+	}
+
+	if !walkNode(n.children[0], buf, yield) {
+		return false
+	}
+
+	return walkNode(n.children[1], buf, yield)
+}
+
+// RSTrie is a Trie specialized for plain route summarization, with no value attached to each route.
+type RSTrie struct {
+	*Trie[struct{}]
+}
+
+// NewRSTrie returns an initialized RSTrie for use.
+func NewRSTrie() *RSTrie {
+	return &RSTrie{Trie: NewTrie[struct{}]()}
+}
+
+// InsertRoute inserts a new BitSlice into the trie. Each insert results in a space-optimized trie structure
+// representing its contents. If a route being inserted is already covered by an existing route, it's simply ignored. If
+// a route being inserted covers one or more routes already in the trie, those nodes are removed and replaced by the new
+// route.
+func (t *RSTrie) InsertRoute(routeBits bitslice.BitSlice) {
+	t.Insert(routeBits, struct{}{})
+}
+
+// RemoveRoute removes routeBits from the address space covered by the trie. See Trie.Remove for details.
+func (t *RSTrie) RemoveRoute(routeBits bitslice.BitSlice) {
+	t.Remove(routeBits)
+}
+
+// LongestMatch returns the longest stored route that covers bits, and whether such a route was found.
+func (t *RSTrie) LongestMatch(bits bitslice.BitSlice) (bitslice.BitSlice, bool) {
+	matchBits, _, ok := t.Trie.LongestMatch(bits)
+	return matchBits, ok
+}
+
+// Union returns a new RSTrie covering every route covered by t, other, or both. Since both tries are
+// already canonicalized, this walks them in lockstep, one radix-2 node at a time, rather than reinserting
+// every route of either trie from scratch.
+func (t *RSTrie) Union(other *RSTrie) *RSTrie {
+	return &RSTrie{Trie: &Trie[struct{}]{root: unionNode(t.root, other.root)}}
+}
+
+// Subtract returns a new RSTrie covering every route covered by t but not other, computed with the same
+// lockstep node-by-node merge as Union.
+func (t *RSTrie) Subtract(other *RSTrie) *RSTrie {
+	return &RSTrie{Trie: &Trie[struct{}]{root: subtractNode(t.root, other.root)}}
+}
+
+// Intersect returns a new RSTrie covering every route covered by both t and other, computed with the same
+// lockstep node-by-node merge as Union.
+func (t *RSTrie) Intersect(other *RSTrie) *RSTrie {
+	return &RSTrie{Trie: &Trie[struct{}]{root: intersectNode(t.root, other.root)}}
+}
+
+// unionNode, intersectNode, and subtractNode each combine a and b, two RSTrie subtries anchored at the same
+// position in the address space (same consumed prefix so far, possibly with differently-lengthed bits runs
+// of their own), into a fresh subtrie anchored at that same position. A nil argument means "nothing covered
+// here". A leaf only covers its whole subtree once its own bits run has actually been matched that far in
+// lockstep with the other operand (commonLen reaching its length) - a bare isLeaf() check without first
+// aligning bits would wrongly treat a leaf as covering branches its own bits never reached.
+func unionNode(a, b *node[struct{}]) *node[struct{}] {
+	if a == nil {
+		return cloneNode(b)
+	}
+	if b == nil {
+		return cloneNode(a)
+	}
+
+	commonLen := commonPrefixLen(a.bits, b.bits)
+
+	switch {
+	case commonLen == len(a.bits) && a.isLeaf():
+		return &node[struct{}]{bits: append(bitslice.BitSlice{}, a.bits...)}
+	case commonLen == len(b.bits) && b.isLeaf():
+		return &node[struct{}]{bits: append(bitslice.BitSlice{}, b.bits...)}
+	default:
+		return mergeInternal(a, b, commonLen, unionNode, cloneNode, cloneNode)
+	}
+}
+
+func intersectNode(a, b *node[struct{}]) *node[struct{}] {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	commonLen := commonPrefixLen(a.bits, b.bits)
+
+	switch {
+	case commonLen == len(a.bits) && a.isLeaf():
+		return cloneNode(b)
+	case commonLen == len(b.bits) && b.isLeaf():
+		return cloneNode(a)
+	default:
+		return mergeInternal(a, b, commonLen, intersectNode, discardNode, discardNode)
+	}
+}
+
+func subtractNode(a, b *node[struct{}]) *node[struct{}] {
+	if a == nil {
+		return nil
+	}
+	if b == nil {
+		return cloneNode(a)
+	}
+
+	commonLen := commonPrefixLen(a.bits, b.bits)
+
+	switch {
+	case commonLen == len(b.bits) && b.isLeaf():
+		return nil
+	case commonLen == len(a.bits) && a.isLeaf():
+		return complementNode(a, b, commonLen)
+	default:
+		return mergeInternal(a, b, commonLen, subtractNode, cloneNode, discardNode)
+	}
+}
+
+// mergeInternal combines a and b once neither operand's leaf status alone resolves the result, aligning
+// their bits runs via the already-computed commonLen and recursing per branch: where both operands still
+// have a matching branch, combine handles it; where a branch exists in only one operand, onlyA or onlyB
+// decides what becomes of it (e.g. Union keeps it via cloneNode, Intersect drops it via discardNode). By
+// construction, every node reached through a.children/b.children already carries its own selector bit as
+// the first element of its bits run, so trimBits(n, commonLen) - not commonLen+1 - is what lines a node's
+// bits back up with a real child's.
+func mergeInternal(
+	a, b *node[struct{}],
+	commonLen int,
+	combine func(a, b *node[struct{}]) *node[struct{}],
+	onlyA, onlyB func(n *node[struct{}]) *node[struct{}],
+) *node[struct{}] {
+	switch {
+	case commonLen == len(a.bits) && commonLen == len(b.bits):
+		left := combine(a.children[0], b.children[0])
+		right := combine(a.children[1], b.children[1])
+		return assembleNode(a.bits, left, right)
+
+	case commonLen == len(a.bits):
+		// a's bits run ends here; only one of its two branches continues on into b.
+		bit := b.bits[commonLen]
+		matched := combine(a.children[bit], trimBits(b, commonLen))
+		untouched := onlyA(a.children[flipBit(bit)])
+
+		if bit == 0 {
+			return assembleNode(a.bits, matched, untouched)
+		}
+		return assembleNode(a.bits, untouched, matched)
+
+	case commonLen == len(b.bits):
+		// b's bits run ends here; only one of its two branches continues on into a.
+		bit := a.bits[commonLen]
+		matched := combine(trimBits(a, commonLen), b.children[bit])
+		untouched := onlyB(b.children[flipBit(bit)])
+
+		if bit == 0 {
+			return assembleNode(b.bits, matched, untouched)
+		}
+		return assembleNode(b.bits, untouched, matched)
+
+	default:
+		// Neither bits run is exhausted yet, and they diverge: the two operands share nothing beyond the
+		// common prefix, so each side's remaining structure belongs to it alone.
+		aBit := a.bits[commonLen]
+
+		aBranch := onlyA(trimBits(a, commonLen))
+		bBranch := onlyB(trimBits(b, commonLen))
+
+		if aBit == 0 {
+			return assembleNode(a.bits[:commonLen], aBranch, bBranch)
+		}
+		return assembleNode(a.bits[:commonLen], bBranch, aBranch)
+	}
+}
+
+// complementNode returns the subtrie covering every address under leaf a that b doesn't cover. subtractNode
+// uses it once a is a leaf whose whole bits run b has matched (commonLen, as computed there): a is split
+// into its two halves under the same bits, and mergeInternal subtracts b from each, so the result never
+// covers anything outside a.
+func complementNode(a, b *node[struct{}], commonLen int) *node[struct{}] {
+	halves := &node[struct{}]{
+		bits:     a.bits,
+		children: &[2]*node[struct{}]{{bits: bitslice.BitSlice{0}}, {bits: bitslice.BitSlice{1}}},
+	}
+
+	return mergeInternal(halves, b, commonLen, subtractNode, cloneNode, discardNode)
+}
+
+// discardNode is an onlyA/onlyB callback for mergeInternal that drops a branch present in only one operand,
+// used by Intersect, where such a branch can never be covered by both.
+func discardNode(*node[struct{}]) *node[struct{}] {
+	return nil
+}
+
+// cloneNode returns a deep copy of n, so that a merge result never aliases the tries it was built from.
+func cloneNode(n *node[struct{}]) *node[struct{}] {
+	if n == nil {
+		return nil
+	}
+
+	clone := &node[struct{}]{bits: append(bitslice.BitSlice{}, n.bits...)}
+	if !n.isLeaf() {
+		clone.children = &[2]*node[struct{}]{cloneNode(n.children[0]), cloneNode(n.children[1])}
+	}
+
+	return clone
+}
+
+// trimBits returns a view of n as if its first k bits had already been consumed, sharing n's underlying
+// bits array and children rather than copying them. It's only ever passed on as an argument to a merge
+// function, never retained in a result, so the aliasing is safe.
+func trimBits(n *node[struct{}], k int) *node[struct{}] {
+	return &node[struct{}]{bits: n.bits[k:], children: n.children}
+}
+
+// assembleNode builds the node for prefix with the given left (bit 0) and right (bit 1) branches, preserving
+// the "0 or 2 children" invariant: a nil left or right causes the other branch to be spliced directly onto
+// prefix instead, and two nil branches collapse to nil. Two leaf branches that together complete prefix's
+// subtrie are folded into a single leaf, the same simplification Insert performs.
+func assembleNode(prefix bitslice.BitSlice, left, right *node[struct{}]) *node[struct{}] {
+	switch {
+	case left == nil && right == nil:
+		return nil
+	case left == nil:
+		return spliceBranch(prefix, right)
+	case right == nil:
+		return spliceBranch(prefix, left)
+	}
+
+	n := &node[struct{}]{
+		bits:     append(bitslice.BitSlice{}, prefix...),
+		children: &[2]*node[struct{}]{left, right},
+	}
+
+	if n.childrenAreCompleteSubtrie() {
+		n.children = nil
+	}
+
+	return n
+}
+
+// spliceBranch folds branch's own bits (which already start with whichever bit selected it) onto prefix,
+// for when a merge leaves only one branch of a node standing - the same splicing Remove does when a node's
+// sibling absorbs its parent's prefix.
+func spliceBranch(prefix bitslice.BitSlice, branch *node[struct{}]) *node[struct{}] {
+	bits := append(append(bitslice.BitSlice{}, prefix...), branch.bits...)
+
+	return &node[struct{}]{bits: bits, children: branch.children}
+}
@@ -0,0 +1,70 @@
+package rstrie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/PatrickCronin/routesum/pkg/routesum/bitslice"
+)
+
+// TestRSTrieSetOps checks Union, Intersect, and Subtract against every possible 8-bit address, for several
+// random route sets, since the lockstep node-by-node merge they're built on has to agree with plain
+// membership regardless of how each operand's trie happens to be shaped.
+func TestRSTrieSetOps(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for trial := 0; trial < 20; trial++ {
+		a := NewRSTrie()
+		b := NewRSTrie()
+
+		for _, route := range randomRoutes(rng, 10) {
+			a.InsertRoute(route)
+		}
+		for _, route := range randomRoutes(rng, 10) {
+			b.InsertRoute(route)
+		}
+
+		union := a.Union(b)
+		intersect := a.Intersect(b)
+		subtract := a.Subtract(b)
+
+		for addr := 0; addr < 256; addr++ {
+			query := byteBits(byte(addr))
+
+			aHas := a.Contains(query)
+			bHas := b.Contains(query)
+
+			if got, want := union.Contains(query), aHas || bHas; got != want {
+				t.Fatalf("trial %d: Union.Contains(%08b) = %v, want %v", trial, addr, got, want)
+			}
+			if got, want := intersect.Contains(query), aHas && bHas; got != want {
+				t.Fatalf("trial %d: Intersect.Contains(%08b) = %v, want %v", trial, addr, got, want)
+			}
+			if got, want := subtract.Contains(query), aHas && !bHas; got != want {
+				t.Fatalf("trial %d: Subtract.Contains(%08b) = %v, want %v", trial, addr, got, want)
+			}
+		}
+	}
+}
+
+func randomRoutes(rng *rand.Rand, count int) []bitslice.BitSlice {
+	routes := make([]bitslice.BitSlice, count)
+	for i := range routes {
+		bits := make(bitslice.BitSlice, 1+rng.Intn(8))
+		for j := range bits {
+			bits[j] = byte(rng.Intn(2))
+		}
+		routes[i] = bits
+	}
+
+	return routes
+}
+
+func byteBits(b byte) bitslice.BitSlice {
+	bits := make(bitslice.BitSlice, 8)
+	for i := 0; i < 8; i++ {
+		bits[i] = (b >> (7 - i)) & 1
+	}
+
+	return bits
+}
@@ -0,0 +1,211 @@
+package rstrie
+
+import "github.com/PatrickCronin/routesum/pkg/routesum/bitslice"
+
+// StrideTrie is an alternative implementation of the same summarizing trie as RSTrie, based on the
+// Allotment Routing Table (ART) algorithm. Instead of descending one bit at a time, it descends a fixed
+// 8-bit stride at a time, trading a larger, constant-size table per stride for far fewer pointer-chasing
+// steps on insert and lookup. This matters once a trie holds millions of routes, where RSTrie's
+// one-bit-at-a-time descent dominates cost.
+type StrideTrie struct {
+	root *strideNode
+}
+
+const (
+	strideWidth     = 8
+	strideHostCount = 1 << strideWidth    // 256 possible full-width values in a stride
+	strideTableSize = strideHostCount * 2 // heap indices for prefix lengths 0..8 within a stride
+)
+
+// strideNode holds one 8-bit stride's worth of the trie. entries records, for every possible prefix length
+// 0 through 8 within this stride (addressed via heapIndex), whether that prefix is covered by a stored
+// route. children holds, for each of the 256 possible full-width values, the next stride's node, present
+// only where the trie has more specific routes below it.
+type strideNode struct {
+	entries  [strideTableSize]bool
+	children [strideHostCount]*strideNode
+}
+
+// NewStrideTrie returns an initialized StrideTrie for use.
+func NewStrideTrie() *StrideTrie {
+	return &StrideTrie{root: &strideNode{}}
+}
+
+// heapIndex maps a prefix of up to 8 bits onto its position in the complete binary tree of length-8
+// prefixes: heapIndex(p) = (1 << len(p)) | value(p).
+func heapIndex(bits bitslice.BitSlice) int {
+	return (1 << len(bits)) | bitsToInt(bits)
+}
+
+func bitsToInt(bits bitslice.BitSlice) int {
+	v := 0
+	for _, b := range bits {
+		v = v<<1 | int(b)
+	}
+
+	return v
+}
+
+// allot marks idx, and every descendant of idx in entries that currently carries oldVal, with newVal. This
+// is how inserting a short prefix makes every more specific prefix beneath it covered too.
+func allot(entries *[strideTableSize]bool, idx int, oldVal, newVal bool) {
+	if entries[idx] != oldVal {
+		return
+	}
+
+	entries[idx] = newVal
+	if idx < strideHostCount {
+		allot(entries, 2*idx, oldVal, newVal)
+		allot(entries, 2*idx+1, oldVal, newVal)
+	}
+}
+
+// bubbleUp collapses idx with its sibling into their shared parent wherever both are covered and the
+// parent isn't already marked, mirroring the same-value sibling merge RSTrie performs on insert.
+func bubbleUp(entries *[strideTableSize]bool, idx int) {
+	for idx > 1 {
+		parent := idx / 2
+		sibling := idx ^ 1
+		if entries[parent] || !entries[idx] || !entries[sibling] {
+			return
+		}
+
+		entries[parent] = true
+		idx = parent
+	}
+}
+
+type strideVisit struct {
+	node    *strideNode
+	hostIdx int
+}
+
+// InsertRoute inserts routeBits into the trie, with the same semantics as RSTrie.InsertRoute: a route
+// already covered by a stored one is ignored, and routes covered by the new one are replaced by it.
+func (t *StrideTrie) InsertRoute(routeBits bitslice.BitSlice) {
+	node := t.root
+	remaining := routeBits
+	visited := []strideVisit{}
+
+	for len(remaining) > strideWidth {
+		hostIdx := bitsToInt(remaining[:strideWidth])
+
+		if node.entries[strideHostCount+hostIdx] {
+			// A shorter route already covers this entire byte.
+			return
+		}
+
+		if node.children[hostIdx] == nil {
+			node.children[hostIdx] = &strideNode{}
+		}
+
+		visited = append(visited, strideVisit{node: node, hostIdx: hostIdx})
+		node = node.children[hostIdx]
+		remaining = remaining[strideWidth:]
+	}
+
+	idx := heapIndex(remaining)
+	allot(&node.entries, idx, false, true)
+	bubbleUp(&node.entries, idx)
+
+	// If the deepest stride ends up fully covered, fold it into its parent's entry and discard it, and
+	// keep folding up the chain for as long as doing so completes the stride above it too.
+	for i := len(visited) - 1; i >= 0 && node.entries[1]; i-- {
+		parent := visited[i].node
+		hostIdx := visited[i].hostIdx
+
+		parent.children[hostIdx] = nil
+		allot(&parent.entries, strideHostCount+hostIdx, false, true)
+		bubbleUp(&parent.entries, strideHostCount+hostIdx)
+		node = parent
+	}
+}
+
+// Contains returns whether bits is covered by a route already stored in the trie.
+func (t *StrideTrie) Contains(bits bitslice.BitSlice) bool {
+	_, ok := t.LongestMatch(bits)
+	return ok
+}
+
+// LongestMatch returns the longest stored route that covers bits, and whether such a route was found. It
+// walks the trie stride by stride and returns as soon as it sees a covered entry, exactly as strideWalk
+// does: allot marks every descendant of a covered index as covered too, and any entries left set by a more
+// specific route that this one has since absorbed stay set beneath it, so deeper covered entries are never
+// routes in their own right and must never be reported as a longer match.
+func (t *StrideTrie) LongestMatch(bits bitslice.BitSlice) (bitslice.BitSlice, bool) {
+	node := t.root
+	remaining := bits
+	consumed := bitslice.BitSlice{}
+
+	for {
+		strideLen := len(remaining)
+		if strideLen > strideWidth {
+			strideLen = strideWidth
+		}
+
+		for l := 0; l <= strideLen; l++ {
+			if node.entries[heapIndex(remaining[:l])] {
+				return append(append(bitslice.BitSlice{}, consumed...), remaining[:l]...), true
+			}
+		}
+
+		if strideLen < strideWidth {
+			return nil, false
+		}
+
+		hostIdx := bitsToInt(remaining[:strideWidth])
+		child := node.children[hostIdx]
+		if child == nil {
+			return nil, false
+		}
+
+		consumed = append(consumed, remaining[:strideWidth]...)
+		remaining = remaining[strideWidth:]
+		node = child
+	}
+}
+
+// Contents returns the BitSlices contained in the StrideTrie, as the minimal set of CIDR-style bit
+// sequences needed to represent everything it covers.
+func (t *StrideTrie) Contents() []bitslice.BitSlice {
+	contents := []bitslice.BitSlice{}
+	strideWalk(t.root, 1, bitslice.BitSlice{}, &contents)
+	return contents
+}
+
+// strideWalk collects the minimal covering routes for idx, and, if idx isn't itself covered, for its
+// descendants, including any child stride table hanging off a length-8 host index.
+func strideWalk(n *strideNode, idx int, prefix bitslice.BitSlice, contents *[]bitslice.BitSlice) {
+	if n.entries[idx] {
+		*contents = append(*contents, append(append(bitslice.BitSlice{}, prefix...), indexBits(idx)...))
+		return
+	}
+
+	if idx < strideHostCount {
+		strideWalk(n, 2*idx, prefix, contents)
+		strideWalk(n, 2*idx+1, prefix, contents)
+		return
+	}
+
+	if child := n.children[idx-strideHostCount]; child != nil {
+		childPrefix := append(append(bitslice.BitSlice{}, prefix...), indexBits(idx)...)
+		strideWalk(child, 1, childPrefix, contents)
+	}
+}
+
+// indexBits returns the bits, relative to the current stride, represented by a heap index: the inverse of
+// heapIndex.
+func indexBits(idx int) bitslice.BitSlice {
+	length := 0
+	for shifted := idx; shifted > 1; shifted >>= 1 {
+		length++
+	}
+
+	bits := make(bitslice.BitSlice, length)
+	for i := length - 1; i >= 0; i-- {
+		bits[i] = byte(idx & 1)
+		idx >>= 1
+	}
+
+	return bits
+}
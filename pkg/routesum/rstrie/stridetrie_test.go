@@ -0,0 +1,134 @@
+package rstrie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/PatrickCronin/routesum/pkg/routesum/bitslice"
+)
+
+// TestStrideTrieLongestMatchAfterBroaderInsert covers inserting a broader route after a narrower one it
+// subsumes, which regressed LongestMatch: allot leaves the narrower route's now-redundant entries marked
+// covered, and LongestMatch must stop at the shallowest covered entry rather than keep scanning into them.
+func TestStrideTrieLongestMatchAfterBroaderInsert(t *testing.T) {
+	trie := NewStrideTrie()
+	trie.InsertRoute(bitslice.BitSlice{1, 0, 0, 0})
+	trie.InsertRoute(bitslice.BitSlice{1, 0})
+
+	match, ok := trie.LongestMatch(bitslice.BitSlice{1, 0, 0, 0, 0, 0, 0, 0})
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if len(match) != 2 {
+		t.Fatalf("expected the 2-bit route to win after absorbing the more specific one, got %d bits (%v)", len(match), match)
+	}
+
+	contents := trie.Contents()
+	if len(contents) != 1 || len(contents[0]) != 2 {
+		t.Fatalf("expected Contents to report a single 2-bit route, got %v", contents)
+	}
+}
+
+// TestStrideTrieMatchesRSTrie inserts the same random routes into a StrideTrie and an RSTrie and checks that
+// Contents holds the same number of routes and that Contains and LongestMatch agree, since both are meant to implement the same summarizing
+// semantics behind a common interface. StrideTrie has no RemoveRoute, so unlike RSTrie this only covers
+// insertion.
+func TestStrideTrieMatchesRSTrie(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	stride := NewStrideTrie()
+	radix := NewRSTrie()
+
+	routes := randomBitSlices(rng, 500, 12)
+	for _, route := range routes {
+		stride.InsertRoute(route)
+		radix.InsertRoute(route)
+	}
+
+	if got, want := len(stride.Contents()), len(radix.Contents()); got != want {
+		t.Fatalf("Contents length mismatch: stride has %d routes, radix has %d", got, want)
+	}
+
+	for _, query := range randomBitSlices(rng, 200, 12) {
+		wantMatch, wantOK := radix.LongestMatch(query)
+		gotMatch, gotOK := stride.LongestMatch(query)
+
+		if gotOK != wantOK {
+			t.Fatalf("LongestMatch(%v): found mismatch, stride=%v radix=%v", query, gotOK, wantOK)
+		}
+		if gotOK && len(gotMatch) != len(wantMatch) {
+			t.Fatalf("LongestMatch(%v): stride returned %d bits, radix returned %d bits", query, len(gotMatch), len(wantMatch))
+		}
+
+		if got, want := stride.Contains(query), radix.Contains(query); got != want {
+			t.Fatalf("Contains(%v): stride=%v radix=%v", query, got, want)
+		}
+	}
+}
+
+func randomBitSlices(rng *rand.Rand, count, maxLen int) []bitslice.BitSlice {
+	routes := make([]bitslice.BitSlice, count)
+	for i := range routes {
+		bits := make(bitslice.BitSlice, 1+rng.Intn(maxLen))
+		for j := range bits {
+			bits[j] = byte(rng.Intn(2))
+		}
+		routes[i] = bits
+	}
+
+	return routes
+}
+
+// BenchmarkStrideTrieInsert and BenchmarkRSTrieInsert compare insert cost between the two backends, the
+// motivation for StrideTrie in the first place.
+func BenchmarkStrideTrieInsert(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	routes := randomBitSlices(rng, b.N, 32)
+
+	b.ResetTimer()
+	trie := NewStrideTrie()
+	for _, route := range routes {
+		trie.InsertRoute(route)
+	}
+}
+
+func BenchmarkRSTrieInsert(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	routes := randomBitSlices(rng, b.N, 32)
+
+	b.ResetTimer()
+	trie := NewRSTrie()
+	for _, route := range routes {
+		trie.InsertRoute(route)
+	}
+}
+
+// BenchmarkStrideTrieLongestMatch and BenchmarkRSTrieLongestMatch compare lookup cost once a large number of
+// routes are already loaded.
+func BenchmarkStrideTrieLongestMatch(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	trie := NewStrideTrie()
+	for _, route := range randomBitSlices(rng, 100_000, 32) {
+		trie.InsertRoute(route)
+	}
+	queries := randomBitSlices(rng, b.N, 32)
+
+	b.ResetTimer()
+	for _, query := range queries {
+		trie.LongestMatch(query)
+	}
+}
+
+func BenchmarkRSTrieLongestMatch(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	trie := NewRSTrie()
+	for _, route := range randomBitSlices(rng, 100_000, 32) {
+		trie.InsertRoute(route)
+	}
+	queries := randomBitSlices(rng, b.N, 32)
+
+	b.ResetTimer()
+	for _, query := range queries {
+		trie.LongestMatch(query)
+	}
+}
@@ -0,0 +1,97 @@
+package routesum
+
+import (
+	"net/netip"
+
+	"github.com/stamp/routesum/pkg/routesum/rstrie"
+)
+
+// Table is a value-carrying counterpart to RouteSum: each stored prefix carries a value of type T, and two
+// sibling prefixes are only merged into a shorter summary route when their values compare equal.
+type Table[T comparable] struct {
+	ipv4, ipv6 *rstrie.Trie[T]
+}
+
+// NewTable returns an initialized Table for use
+func NewTable[T comparable]() *Table[T] {
+	t := new(Table[T])
+	t.ipv4 = rstrie.NewTrie[T]()
+	t.ipv6 = rstrie.NewTrie[T]()
+
+	return t
+}
+
+// Insert associates value with p in the table.
+func (t *Table[T]) Insert(p netip.Prefix, value T) error {
+	ipBits, err := ipBitsForIPPrefix(p)
+	if err != nil {
+		return err
+	}
+
+	if p.Addr().Is4() {
+		t.ipv4.Insert(ipBits, value)
+	} else {
+		t.ipv6.Insert(ipBits, value)
+	}
+
+	return nil
+}
+
+// Remove removes p, and its associated value, from the table.
+func (t *Table[T]) Remove(p netip.Prefix) error {
+	ipBits, err := ipBitsForIPPrefix(p)
+	if err != nil {
+		return err
+	}
+
+	if p.Addr().Is4() {
+		t.ipv4.Remove(ipBits)
+	} else {
+		t.ipv6.Remove(ipBits)
+	}
+
+	return nil
+}
+
+// Get returns the value associated with the exact prefix p, and whether one was found.
+func (t *Table[T]) Get(p netip.Prefix) (T, bool) {
+	var zero T
+
+	ipBits, err := ipBitsForIPPrefix(p)
+	if err != nil {
+		return zero, false
+	}
+
+	if p.Addr().Is4() {
+		return t.ipv4.Get(ipBits)
+	}
+
+	return t.ipv6.Get(ipBits)
+}
+
+// LongestPrefixMatch returns the longest stored prefix covering ip, its associated value, and whether a
+// match was found.
+func (t *Table[T]) LongestPrefixMatch(ip netip.Addr) (netip.Prefix, T, bool) {
+	var zero T
+
+	ipBits, err := ipBitsForIP(ip)
+	if err != nil {
+		return netip.Prefix{}, zero, false
+	}
+
+	if ip.Is4() {
+		matchBits, value, ok := t.ipv4.LongestMatch(ipBits)
+		if !ok {
+			return netip.Prefix{}, zero, false
+		}
+
+		return netip.PrefixFrom(ipv4FromBits(matchBits), len(matchBits)), value, true
+	}
+
+	matchBits, value, ok := t.ipv6.LongestMatch(ipBits)
+	if !ok {
+		return netip.Prefix{}, zero, false
+	}
+
+	return netip.PrefixFrom(ipv6FromBits(matchBits), len(matchBits)), value, true
+}